@@ -1,17 +1,48 @@
 package main
 
 import (
+	"archive/tar"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"time"
 
 	"github.com/u-root/u-root/pkg/ldd"
 )
 
+// BinaryKind classifies an ELF executable the way lib4bin needs to treat it:
+// whether it needs its shared libraries resolved and bundled alongside it.
+type BinaryKind int
+
+const (
+	BinaryStatic    BinaryKind = iota // No PT_INTERP, and not an ET_DYN file with PT_DYNAMIC: fully self-contained.
+	BinaryStaticPIE                   // No PT_INTERP, ET_DYN with PT_DYNAMIC: a position-independent binary with no external deps.
+	BinaryDynamic                     // Has PT_INTERP: needs its shared libraries copied in.
+)
+
+func (k BinaryKind) String() string {
+	switch k {
+	case BinaryStatic:
+		return "static"
+	case BinaryStaticPIE:
+		return "static PIE"
+	case BinaryDynamic:
+		return "dynamic"
+	default:
+		return "unknown"
+	}
+}
+
 // Constants for directory structure
 const (
 	defaultDstDir    = "output"
@@ -25,39 +56,95 @@ var (
 	oneDir      = flag.Bool("one-dir", true, "Use one directory for output")
 	createLinks = flag.Bool("create-links", true, "Create symlinks in the bin directory")
 	dstDirPath  = flag.String("dst-dir", defaultDstDir, "Destination directory for libraries and binaries")
+	format      = flag.String("format", formatDir, "Output format for -dst-dir: dir, squashfs, or oci-layout")
 )
 
-// tryStrip attempts to strip the binary if the flag is set
-func tryStrip(filePath string) error {
-	if *strip {
-		stripPath, err := exec.LookPath("strip")
-		if err != nil {
-			return fmt.Errorf("strip command not found: %v", err)
-		}
+// Supported -format values.
+const (
+	formatDir       = "dir"
+	formatSquashfs  = "squashfs"
+	formatOCILayout = "oci-layout"
+)
 
-		// Execute the strip command
-		cmd := exec.Command(stripPath, filePath)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to strip %s: %v", filePath, err)
-		}
+// tryStrip attempts to strip the binary if the flag is set. It refuses to
+// strip a binary built for a different architecture than the host, since
+// running the host's strip on a foreign-arch ELF silently corrupts it rather
+// than failing cleanly.
+func tryStrip(filePath string, machine elf.Machine) error {
+	if !*strip {
+		return nil
+	}
+
+	if hostMachine, ok := hostELFMachine(); ok && machine != hostMachine {
+		return fmt.Errorf("refusing to strip %s: built for %s, host is %s", filePath, machine, hostMachine)
+	}
+
+	stripPath, err := exec.LookPath("strip")
+	if err != nil {
+		return fmt.Errorf("strip command not found: %v", err)
+	}
+
+	// Execute the strip command
+	cmd := exec.Command(stripPath, filePath)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to strip %s: %v", filePath, err)
 	}
 	return nil
 }
 
-func isDynamicExecutable(binaryPath string) (bool, error) {
-	cmd := exec.Command("ldd", binaryPath)
-	output, err := cmd.CombinedOutput()
+// classifyBinary opens binaryPath as an ELF file and determines its
+// BinaryKind from its program headers: a PT_INTERP entry means it needs a
+// dynamic linker (and therefore its shared libraries resolved), while a
+// PT_DYNAMIC entry with no interpreter in an ET_DYN file marks a static PIE
+// rather than a plain static binary.
+func classifyBinary(binaryPath string) (BinaryKind, elf.Machine, error) {
+	f, err := elf.Open(binaryPath)
 	if err != nil {
-		return false, nil
+		return 0, 0, fmt.Errorf("failed to parse ELF file %s: %w", binaryPath, err)
 	}
-	outputStr := strings.TrimSpace(string(output))
+	defer f.Close()
 
-	// Check if the binary is static
-	outputLower := strings.ToLower(outputStr)
-	if strings.Contains(outputLower, "not a dynamic executable") || strings.Contains(outputLower, "not a valid dynamic program") {
-		return false, nil // It's static
+	var hasInterp, hasDynamic bool
+	for _, prog := range f.Progs {
+		switch prog.Type {
+		case elf.PT_INTERP:
+			hasInterp = true
+		case elf.PT_DYNAMIC:
+			hasDynamic = true
+		}
+	}
+
+	switch {
+	case hasInterp:
+		return BinaryDynamic, f.Machine, nil
+	case f.Type == elf.ET_DYN && hasDynamic:
+		return BinaryStaticPIE, f.Machine, nil
+	default:
+		return BinaryStatic, f.Machine, nil
+	}
+}
+
+// hostELFMachine returns the elf.Machine value matching runtime.GOARCH, so a
+// target binary's architecture can be compared against the host's.
+func hostELFMachine() (elf.Machine, bool) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return elf.EM_X86_64, true
+	case "386":
+		return elf.EM_386, true
+	case "arm64":
+		return elf.EM_AARCH64, true
+	case "arm":
+		return elf.EM_ARM, true
+	case "riscv64":
+		return elf.EM_RISCV, true
+	case "ppc64", "ppc64le":
+		return elf.EM_PPC64, true
+	case "s390x":
+		return elf.EM_S390, true
+	default:
+		return 0, false
 	}
-	return true, nil
 }
 
 // copyFile copies a file from source to destination
@@ -83,6 +170,31 @@ func findDynExec() (string, error) {
 	return path, nil
 }
 
+// processSelfContainedBinary handles a BinaryStatic or BinaryStaticPIE binary,
+// neither of which needs a dynamic linker or shared libraries resolved.
+// allowStrip gates whether -strip is honored for this kind: stripping a
+// static PIE binary can remove the .dynsym/.rela.dyn it needs to self-relocate.
+func processSelfContainedBinary(binaryPath string, fileInfo os.FileInfo, kind BinaryKind, machine elf.Machine, allowStrip bool) error {
+	binDir := filepath.Join(*dstDirPath, defaultBinDir)
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+	dstBinaryPath := filepath.Join(binDir, fileInfo.Name())
+	if err := copyFile(binaryPath, dstBinaryPath); err != nil {
+		return err
+	}
+	if allowStrip {
+		if err := tryStrip(dstBinaryPath, machine); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(dstBinaryPath, 0755); err != nil {
+		return err
+	}
+	fmt.Printf("Processed %s binary: %s\n", kind, fileInfo.Name())
+	return nil
+}
+
 func processBinary(binaryPath string) error {
 	fileInfo, err := os.Stat(binaryPath)
 	if err != nil {
@@ -93,8 +205,7 @@ func processBinary(binaryPath string) error {
 		return fmt.Errorf("skipped: %s is not a regular file", binaryPath)
 	}
 
-	// Check if the binary is dynamic
-	isDynamic, err := isDynamicExecutable(binaryPath)
+	kind, machine, err := classifyBinary(binaryPath)
 	if err != nil {
 		return err
 	}
@@ -104,24 +215,16 @@ func processBinary(binaryPath string) error {
 		return err
 	}
 
-	if !isDynamic {
-		// Handle static binaries
-		binDir := filepath.Join(*dstDirPath, defaultBinDir)
-		if err := os.MkdirAll(binDir, 0755); err != nil {
-			return err
-		}
-		dstBinaryPath := filepath.Join(binDir, fileInfo.Name())
-		if err := copyFile(binaryPath, dstBinaryPath); err != nil {
-			return err
-		}
-		if err := tryStrip(dstBinaryPath); err != nil {
-			return err
-		}
-		if err := os.Chmod(dstBinaryPath, 0755); err != nil {
-			return err
-		}
-		fmt.Printf("Processed static binary: %s\n", fileInfo.Name())
-		return nil
+	switch kind {
+	case BinaryStatic:
+		// No dynamic linker or shared libraries to resolve, and no PIE
+		// self-relocation that stripping could break, so stripping is allowed.
+		return processSelfContainedBinary(binaryPath, fileInfo, kind, machine, true)
+	case BinaryStaticPIE:
+		// Static PIE binaries self-relocate at load time using their own
+		// .dynsym/.rela.dyn; stripping those away would leave the binary
+		// unable to relocate itself, so -strip is ignored for this kind.
+		return processSelfContainedBinary(binaryPath, fileInfo, kind, machine, false)
 	}
 
 	// Process dynamic binaries
@@ -166,7 +269,7 @@ func processBinary(binaryPath string) error {
 	if err := copyFile(binaryPath, sharedBinaryPath); err != nil {
 		return err
 	}
-	if err := tryStrip(sharedBinaryPath); err != nil {
+	if err := tryStrip(sharedBinaryPath, machine); err != nil {
 		return err
 	}
 
@@ -182,11 +285,11 @@ func processBinary(binaryPath string) error {
 		if err := copyFile(libPath, dstLibPath); err != nil {
 			return err
 		}
-		if err := tryStrip(dstLibPath); err != nil {
+		if err := tryStrip(dstLibPath, machine); err != nil {
 			return err
 		}
 	}
-	fmt.Printf("Processed dynamic binary: %s\n", fileInfo.Name())
+	fmt.Printf("Processed %s binary: %s\n", kind, fileInfo.Name())
 	return nil
 }
 
@@ -217,4 +320,267 @@ func main() {
 			log.Printf("Error processing %s: %v\n", binary, err)
 		}
 	}
+
+	if err := finalizeOutput(*dstDirPath, *format); err != nil {
+		log.Fatalf("Error producing -format %s output: %v\n", *format, err)
+	}
+}
+
+// finalizeOutput converts the plain directory tree written under dstDir into
+// the requested output format. "dir" is the default and requires no further
+// work; "squashfs" and "oci-layout" wrap the same tree into a single
+// artifact consumable by tooling outside of sharun itself.
+func finalizeOutput(dstDir, format string) error {
+	switch format {
+	case formatDir, "":
+		return nil
+	case formatSquashfs:
+		return packSquashfs(dstDir)
+	case formatOCILayout:
+		return packOCILayout(dstDir)
+	default:
+		return fmt.Errorf("unknown -format %q (expected %s, %s, or %s)", format, formatDir, formatSquashfs, formatOCILayout)
+	}
+}
+
+// packSquashfs wraps dstDir into a single dstDir+".sfs" squashfs image via the
+// system mksquashfs, so the output can be mounted or booted directly.
+func packSquashfs(dstDir string) error {
+	mksquashfsPath, err := exec.LookPath("mksquashfs")
+	if err != nil {
+		return fmt.Errorf("mksquashfs not found in PATH: %w", err)
+	}
+
+	sfsPath := dstDir + ".sfs"
+	if err := os.Remove(sfsPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove existing %s: %w", sfsPath, err)
+	}
+
+	cmd := exec.Command(mksquashfsPath, dstDir, sfsPath, "-noappend", "-all-root")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("mksquashfs failed: %w", err)
+	}
+
+	fmt.Printf("Wrote squashfs image: %s\n", sfsPath)
+	return nil
+}
+
+// ociDescriptor is an OCI content descriptor (config/layer/manifest pointer).
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociManifest is a minimal single-layer OCI image manifest.
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+// ociIndex is the top-level "index.json" of an OCI image layout.
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+// ociImageConfig is a minimal OCI image config; sharun's output has no
+// runtime entrypoint of its own, so Config is left empty.
+type ociImageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	RootFS       struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	Config struct{} `json:"config"`
+}
+
+// packOCILayout packages dstDir as the rootfs of a single-layer OCI image,
+// writing a proper OCI image layout (oci-layout + index.json + blobs/sha256)
+// next to it, consumable by skopeo or `podman load`.
+func packOCILayout(dstDir string) error {
+	layoutDir := dstDir + "-oci"
+	blobsDir := filepath.Join(layoutDir, "blobs", "sha256")
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", blobsDir, err)
+	}
+
+	layerDigest, layerSize, err := writeDeterministicLayer(dstDir, blobsDir)
+	if err != nil {
+		return fmt.Errorf("failed to write image layer: %w", err)
+	}
+
+	config := ociImageConfig{Architecture: runtime.GOARCH, OS: "linux"}
+	config.RootFS.Type = "layers"
+	config.RootFS.DiffIDs = []string{"sha256:" + layerDigest}
+
+	configDigest, configSize, err := writeJSONBlob(blobsDir, config)
+	if err != nil {
+		return fmt.Errorf("failed to write image config: %w", err)
+	}
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.manifest.v1+json",
+		Config: ociDescriptor{
+			MediaType: "application/vnd.oci.image.config.v1+json",
+			Digest:    "sha256:" + configDigest,
+			Size:      configSize,
+		},
+		Layers: []ociDescriptor{{
+			MediaType: "application/vnd.oci.image.layer.v1.tar",
+			Digest:    "sha256:" + layerDigest,
+			Size:      layerSize,
+		}},
+	}
+
+	manifestDigest, manifestSize, err := writeJSONBlob(blobsDir, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to write image manifest: %w", err)
+	}
+
+	index := ociIndex{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.index.v1+json",
+		Manifests: []ociDescriptor{{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    "sha256:" + manifestDigest,
+			Size:      manifestSize,
+		}},
+	}
+
+	if err := writeJSONFile(filepath.Join(layoutDir, "index.json"), index); err != nil {
+		return fmt.Errorf("failed to write index.json: %w", err)
+	}
+
+	layoutMarker := []byte(`{"imageLayoutVersion":"1.0.0"}`)
+	if err := os.WriteFile(filepath.Join(layoutDir, "oci-layout"), layoutMarker, 0644); err != nil {
+		return fmt.Errorf("failed to write oci-layout: %w", err)
+	}
+
+	fmt.Printf("Wrote OCI image layout: %s\n", layoutDir)
+	return nil
+}
+
+// writeDeterministicLayer tars srcDir into blobsDir/<sha256 digest>, the
+// layer's content-addressed name under the OCI layout. filepath.WalkDir
+// already visits entries in sorted order and every header gets a fixed
+// mtime and numeric uid/gid 0, so the same input tree always produces the
+// same blob, mirroring how containers/storage's archive package builds
+// reproducible tarballs.
+func writeDeterministicLayer(srcDir, blobsDir string) (digest string, size int64, err error) {
+	tmpPath := filepath.Join(blobsDir, "layer.tmp")
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmpPath)
+
+	hasher := sha256.New()
+	tw := tar.NewWriter(io.MultiWriter(out, hasher))
+
+	walkErr := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		var linkTarget string
+		if d.Type()&os.ModeSymlink != 0 {
+			if linkTarget, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, linkTarget)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid = 0, 0
+		hdr.Uname, hdr.Gname = "", ""
+		hdr.ModTime = time.Unix(0, 0)
+		hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if walkErr != nil {
+		out.Close()
+		return "", 0, walkErr
+	}
+
+	if err := tw.Close(); err != nil {
+		out.Close()
+		return "", 0, err
+	}
+
+	info, err := out.Stat()
+	if err != nil {
+		out.Close()
+		return "", 0, err
+	}
+	size = info.Size()
+	if err := out.Close(); err != nil {
+		return "", 0, err
+	}
+
+	digest = hex.EncodeToString(hasher.Sum(nil))
+	if err := os.Rename(tmpPath, filepath.Join(blobsDir, digest)); err != nil {
+		return "", 0, err
+	}
+	return digest, size, nil
+}
+
+// writeJSONBlob marshals v and writes it to blobsDir under its own sha256
+// digest, returning that digest and the blob's size.
+func writeJSONBlob(blobsDir string, v any) (digest string, size int64, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", 0, err
+	}
+	sum := sha256.Sum256(data)
+	digest = hex.EncodeToString(sum[:])
+	if err := os.WriteFile(filepath.Join(blobsDir, digest), data, 0644); err != nil {
+		return "", 0, err
+	}
+	return digest, int64(len(data)), nil
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
 }
@@ -2,34 +2,80 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"hash/crc64"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
 	"os/user"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/liamg/tml"
 )
 
 const configFilePath = ".config/pelfd.json"
 
+// debounceWindow controls how long we wait after the last fsnotify event on a
+// path before acting on it, so that a burst of writes to the same bundle only
+// triggers a single rescan.
+const debounceWindow = 800 * time.Millisecond
+
 // Options defines the configuration options for the PELFD daemon.
 type Options struct {
-	DirectoriesToWalk   []string `json:"directories_to_walk"`   // Directories to scan for .AppBundle and .blob files.
-	ProbeInterval       int      `json:"probe_interval"`        // Interval in seconds between directory scans.
-	IconDir             string   `json:"icon_dir"`              // Directory to store extracted icons.
-	AppDir              string   `json:"app_dir"`               // Directory to store .desktop files.
-	ProbeExtensions     []string `json:"probe_extensions"`      // File extensions to probe within directories.
-	CorrectDesktopFiles bool     `json:"correct_desktop_files"` // Flag to enable automatic correction of .desktop files.
+	DirectoriesToWalk    []WatchDir `json:"directories_to_walk"`    // Directories to scan for .AppBundle and .blob files.
+	ProbeInterval        int        `json:"probe_interval"`         // Interval in seconds between fallback directory scans; used only when FallbackProbeEnabled is set.
+	IconDir              string     `json:"icon_dir"`               // Directory to store extracted icons.
+	AppDir               string     `json:"app_dir"`                // Directory to store .desktop files.
+	ProbeExtensions      []string   `json:"probe_extensions"`       // File extensions to probe within directories.
+	CorrectDesktopFiles  bool       `json:"correct_desktop_files"`  // Flag to enable automatic correction of .desktop files.
+	FallbackProbeEnabled bool       `json:"fallback_probe_enabled"` // Flag to enable the periodic fallback scan alongside the fsnotify watcher. Off by default.
+}
+
+// WatchDir describes one entry of Options.DirectoriesToWalk. Path may be a
+// plain directory, a glob such as "~/opt/*/bin", or a pattern containing "**"
+// to match every subdirectory under a base path (e.g. "~/Programs/**").
+type WatchDir struct {
+	Path      string `json:"path"`                // Directory, glob, or "**" pattern, relative to "~" for the home directory.
+	Recursive bool   `json:"recursive,omitempty"` // Watch every existing subdirectory of each resolved directory and scan them all for bundles, not just the top level.
+}
+
+// watchDirAlias has the same fields as WatchDir but none of its methods, so
+// decoding into it from UnmarshalJSON doesn't recurse back into UnmarshalJSON.
+type watchDirAlias WatchDir
+
+// UnmarshalJSON accepts both the pre-recursive-glob config shape, where
+// directories_to_walk was a plain []string, and the current object shape, so
+// pelfd.json files written by older versions of the daemon keep loading.
+func (w *WatchDir) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		w.Path = path
+		w.Recursive = false
+		return nil
+	}
+
+	var alias watchDirAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*w = WatchDir(alias)
+	return nil
 }
 
 // Config represents the overall configuration structure for PELFD, including scanning options and a tracker for installed bundles.
@@ -40,15 +86,40 @@ type Config struct {
 
 // BundleEntry represents metadata associated with an installed bundle.
 type BundleEntry struct {
-	Path    string `json:"path"`              // Full path to the bundle file.
-	SHA     string `json:"sha"`               // SHA256 hash of the bundle file.
-	Png     string `json:"png,omitempty"`     // Path to the PNG icon file, if extracted.
-	Xpm     string `json:"xpm,omitempty"`     // Path to the XPM icon file, if extracted.
-	Svg     string `json:"svg,omitempty"`     // Path to the SVG icon file, if extracted.
-	Desktop string `json:"desktop,omitempty"` // Path to the corrected .desktop file, if processed.
+	Path      string    `json:"path"`              // Full path to the bundle file.
+	SHA       string    `json:"sha"`               // SHA256 hash of the bundle file.
+	QuickHash string    `json:"quick_hash"`        // CRC64 over the first/last sample of the file, used to detect a stat drift with no content change.
+	Size      int64     `json:"size"`              // Size of the bundle file, as of the last hash.
+	ModTime   time.Time `json:"mod_time"`          // Modification time of the bundle file, as of the last hash.
+	Png       string    `json:"png,omitempty"`     // Path to the PNG icon file, if extracted.
+	Xpm       string    `json:"xpm,omitempty"`     // Path to the XPM icon file, if extracted.
+	Svg       string    `json:"svg,omitempty"`     // Path to the SVG icon file, if extracted.
+	Desktop   string    `json:"desktop,omitempty"` // Path to the corrected .desktop file, if processed.
 }
 
+const (
+	// shaBufferSize is the read buffer used for the full SHA256 pass.
+	shaBufferSize = 1 << 20 // 1 MiB
+	// quickHashSampleSize is how much of the head/tail of a file to sample for the quick hash.
+	quickHashSampleSize = 64 * 1024
+)
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "install-service":
+			if err := installService(); err != nil {
+				log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to install service: <yellow>%v</yellow>", err))
+			}
+			return
+		case "uninstall-service":
+			if err := uninstallService(); err != nil {
+				log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to uninstall service: <yellow>%v</yellow>", err))
+			}
+			return
+		}
+	}
+
 	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Starting <green>pelfd</green> daemon"))
 
 	usr, err := user.Current()
@@ -67,23 +138,523 @@ func main() {
 		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to create applications directory: <yellow>%v</yellow>", err))
 	}
 
-	probeInterval := time.Duration(config.Options.ProbeInterval) * time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	go signalHandler(ctx, cancel)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to create filesystem watcher: <yellow>%v</yellow>", err))
+	}
+	defer watcher.Close()
+
+	if err := watchDirectories(watcher, config.Options.DirectoriesToWalk, usr.HomeDir); err != nil {
+		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to watch directories: <yellow>%v</yellow>", err))
+	}
+
+	// Initial scan so bundles that already exist are picked up before the first event arrives.
+	processBundle(config, usr.HomeDir)
+
+	// rescanCh carries a single debounced "something changed" signal from the
+	// debouncer's per-path timers (each its own goroutine) back to the main
+	// loop below. The main loop is the only goroutine that ever calls
+	// processBundle, so config.Tracker is never touched from two goroutines at
+	// once; a buffer of 1 plus the non-blocking send in requestRescan means a
+	// burst of timers firing together still only queues one rescan.
+	rescanCh := make(chan struct{}, 1)
+	debouncer := newDebouncer(debounceWindow, func(string) {
+		requestRescan(rescanCh)
+	})
+	defer debouncer.stop()
+
+	var fallbackTicks <-chan time.Time
+	if config.Options.FallbackProbeEnabled {
+		probeInterval := time.Duration(config.Options.ProbeInterval) * time.Second
+		fallbackTicker := time.NewTicker(probeInterval)
+		defer fallbackTicker.Stop()
+		fallbackTicks = fallbackTicker.C
+	}
 
 	for {
-		processBundle(config, usr.HomeDir)
-		time.Sleep(probeInterval)
+		select {
+		case <-ctx.Done():
+			log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Shutting down, flushing config to disk"))
+			saveConfig(config, configPath)
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			handleWatchEvent(watcher, event, config, usr.HomeDir, debouncer)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println(tml.Sprintf("<red><bold>ERR:</bold></red> Watcher error: <yellow>%v</yellow>", err))
+
+		case <-fallbackTicks:
+			log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Running fallback probe"))
+			processBundle(config, usr.HomeDir)
+
+		case <-rescanCh:
+			// Re-resolve glob entries in case a new directory now matches one (e.g.
+			// "~/opt/*/bin" after a package installs ~/opt/foo), then rescan.
+			if err := watchDirectories(watcher, config.Options.DirectoriesToWalk, usr.HomeDir); err != nil {
+				log.Println(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to refresh watched directories: <yellow>%v</yellow>", err))
+			}
+			processBundle(config, usr.HomeDir)
+		}
+	}
+}
+
+// requestRescan queues a rescan signal without blocking. If a signal is
+// already pending, additional triggers are dropped since one pending rescan
+// covers them all.
+func requestRescan(rescanCh chan<- struct{}) {
+	select {
+	case rescanCh <- struct{}{}:
+	default:
+	}
+}
+
+// signalHandler cancels ctx when the process receives SIGINT or SIGTERM, giving
+// main a chance to stop the watchers and persist the config before exiting.
+func signalHandler(ctx context.Context, cancel context.CancelFunc) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	select {
+	case sig := <-sigCh:
+		log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Received signal <yellow>%s</yellow>, shutting down", sig))
+		cancel()
+	case <-ctx.Done():
+	}
+}
+
+// systemdUnitName and launchdLabel identify the installed background service:
+// the systemd unit name on Linux, and the launchd label on macOS.
+const (
+	systemdUnitName = "pelfd.service"
+	launchdLabel    = "tech.pelf.pelfd"
+)
+
+const systemdUnitTemplate = `[Unit]
+Description=pelfd - PELF AppBundle daemon
+
+[Service]
+ExecStart="%s"
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`
+
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+</dict>
+</plist>
+`
+
+// installService writes and activates a background-service definition for the
+// currently running binary: a systemd user unit on Linux, a launchd agent on
+// Darwin.
+func installService() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	if runtime.GOOS == "darwin" {
+		return installLaunchdService(exePath)
+	}
+	return installSystemdService(exePath)
+}
+
+// uninstallService reverses installService.
+func uninstallService() error {
+	if runtime.GOOS == "darwin" {
+		return uninstallLaunchdService()
+	}
+	return uninstallSystemdService()
+}
+
+func systemdUnitPath(homeDir string) string {
+	return filepath.Join(homeDir, ".config/systemd/user", systemdUnitName)
+}
+
+func installSystemdService(exePath string) error {
+	usr, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	unitPath := systemdUnitPath(usr.HomeDir)
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0755); err != nil {
+		return fmt.Errorf("failed to create systemd user directory: %w", err)
+	}
+
+	unit := fmt.Sprintf(systemdUnitTemplate, escapeSystemdExecArg(exePath))
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file %s: %w", unitPath, err)
+	}
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Wrote systemd unit: <green>%s</green>", unitPath))
+
+	if err := runSystemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := runSystemctl("enable", "--now", systemdUnitName); err != nil {
+		return err
+	}
+
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> pelfd installed and started as a systemd user service"))
+	return nil
+}
+
+// escapeSystemdExecArg escapes backslashes and double quotes in s so it can
+// be embedded inside a double-quoted ExecStart= argument, per the unit file
+// quoting rules in systemd.unit(5), keeping paths containing spaces intact.
+func escapeSystemdExecArg(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return s
+}
+
+func uninstallSystemdService() error {
+	usr, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	if err := runSystemctl("disable", "--now", systemdUnitName); err != nil {
+		log.Println(tml.Sprintf("<yellow><bold>WRN:</bold></yellow> Failed to stop/disable systemd service: <yellow>%v</yellow>", err))
+	}
+
+	unitPath := systemdUnitPath(usr.HomeDir)
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove unit file %s: %w", unitPath, err)
+	}
+
+	if err := runSystemctl("daemon-reload"); err != nil {
+		log.Println(tml.Sprintf("<yellow><bold>WRN:</bold></yellow> Failed to reload systemd: <yellow>%v</yellow>", err))
+	}
+
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> pelfd uninstalled from systemd user services"))
+	return nil
+}
+
+func runSystemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func launchdPlistPath(homeDir string) string {
+	return filepath.Join(homeDir, "Library/LaunchAgents", launchdLabel+".plist")
+}
+
+// xmlEscape escapes s for safe embedding as plist character data, so a path
+// containing "&", "<", or similar can't break the generated XML.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return s
+	}
+	return b.String()
+}
+
+func installLaunchdService(exePath string) error {
+	usr, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	plistPath := launchdPlistPath(usr.HomeDir)
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+
+	plist := fmt.Sprintf(launchdPlistTemplate, xmlEscape(launchdLabel), xmlEscape(exePath))
+	if err := os.WriteFile(plistPath, []byte(plist), 0644); err != nil {
+		return fmt.Errorf("failed to write launchd plist %s: %w", plistPath, err)
+	}
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Wrote launchd plist: <green>%s</green>", plistPath))
+
+	cmd := exec.Command("launchctl", "load", plistPath)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("launchctl load failed: %w", err)
+	}
+
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> pelfd installed and loaded as a launchd agent"))
+	return nil
+}
+
+func uninstallLaunchdService() error {
+	usr, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	plistPath := launchdPlistPath(usr.HomeDir)
+	if err := exec.Command("launchctl", "unload", plistPath).Run(); err != nil {
+		log.Println(tml.Sprintf("<yellow><bold>WRN:</bold></yellow> Failed to unload launchd agent: <yellow>%v</yellow>", err))
+	}
+
+	if err := os.Remove(plistPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove plist file %s: %w", plistPath, err)
+	}
+
+	log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> pelfd uninstalled from launchd"))
+	return nil
+}
+
+// watchDirectories resolves every entry in dirs to concrete directories and
+// adds an fsnotify watch for each: recursively when the entry's Recursive
+// flag is set, or just the directory itself otherwise (handleWatchEvent later
+// adds watches for subdirectories created under any watched directory,
+// whatever the entry's Recursive flag). Calling it again (e.g. after a glob entry
+// matches a newly created directory) is safe; re-adding an existing watch is
+// a no-op.
+func watchDirectories(watcher *fsnotify.Watcher, dirs []WatchDir, homeDir string) error {
+	for _, resolved := range resolveWatchDirs(dirs, homeDir) {
+		if resolved.Recursive {
+			if err := addWatchRecursive(watcher, resolved.Path); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", resolved.Path, err)
+			}
+			continue
+		}
+
+		if err := watcher.Add(resolved.Path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", resolved.Path, err)
+		}
+		log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Watching directory: <green>%s</green>", resolved.Path))
+	}
+	return nil
+}
+
+// resolvedDir is a concrete, already-expanded directory to watch/scan, paired
+// with the Recursive setting of the WatchDir entry it came from.
+type resolvedDir struct {
+	Path      string
+	Recursive bool
+}
+
+// resolveWatchDirs expands every WatchDir entry in dirs (substituting "~" for
+// homeDir first) into the concrete, existing directories it matches, and
+// dedupes overlapping matches so a directory reached by two patterns is only
+// reported once.
+func resolveWatchDirs(dirs []WatchDir, homeDir string) []resolvedDir {
+	seen := make(map[string]struct{})
+	var resolved []resolvedDir
+
+	for _, d := range dirs {
+		pattern := strings.Replace(d.Path, "~", homeDir, 1)
+
+		matches, err := expandPattern(pattern)
+		if err != nil {
+			log.Println(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to expand directory pattern <yellow>%s</yellow>: <red>%v</red>", pattern, err))
+			continue
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			if _, dup := seen[match]; dup {
+				continue
+			}
+			seen[match] = struct{}{}
+
+			log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Scanning directory: <green>%s</green>", match))
+			resolved = append(resolved, resolvedDir{Path: match, Recursive: d.Recursive})
+		}
+	}
+
+	return resolved
+}
+
+// expandPattern resolves a single directory pattern to concrete paths. A "**"
+// anywhere in the pattern means "every subdirectory under this base", matched
+// by walking the base directory; a plain path with no wildcard is returned
+// as-is; anything else is left to filepath.Glob, which handles single-level
+// wildcards like "*" or "?" across multiple path segments (e.g. "~/opt/*/bin").
+func expandPattern(pattern string) ([]string, error) {
+	if idx := strings.Index(pattern, "**"); idx != -1 {
+		base := filepath.Clean(pattern[:idx])
+
+		var dirs []string
+		err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dirs, nil
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		// Plain path, no wildcard to expand; resolveWatchDirs filters it out if
+		// it doesn't exist (yet).
+		return []string{pattern}, nil
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// findBundles returns every file under dir matching "*"+ext: just the
+// top-level entries for a non-recursive dir (matching the daemon's original
+// behavior), or every nested match when recursive is set.
+func findBundles(dir, ext string, recursive bool) ([]string, error) {
+	if !recursive {
+		return filepath.Glob(filepath.Join(dir, "*"+ext))
+	}
+
+	var bundles []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ext) {
+			bundles = append(bundles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundles, nil
+}
+
+// addWatchRecursive walks dir and adds a watch for it and every subdirectory.
+func addWatchRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to add watch for %s: %w", path, err)
+		}
+		log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Watching directory: <green>%s</green>", path))
+		return nil
+	})
+}
+
+// handleWatchEvent reacts to a single fsnotify event: every newly created
+// subdirectory of a watched directory gets its own recursive watch (bundles in
+// it are only scanned when it lies under a Recursive entry), and any
+// create/write/remove/rename/chmod is debounced into a rescan.
+func handleWatchEvent(watcher *fsnotify.Watcher, event fsnotify.Event, config Config, homeDir string, debouncer *debouncer) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := addWatchRecursive(watcher, event.Name); err != nil {
+				log.Println(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to watch new directory <yellow>%s</yellow>: <red>%v</red>", event.Name, err))
+			}
+		}
+	}
+
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		// The watch on a removed/renamed directory is no longer valid; fsnotify
+		// drops it internally, but we still try to remove it explicitly in case
+		// the kernel hasn't noticed yet.
+		_ = watcher.Remove(event.Name)
+	}
+
+	if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) || event.Has(fsnotify.Chmod) {
+		debouncer.trigger(event.Name)
+	}
+}
+
+// debouncer coalesces repeated triggers for the same path into a single call
+// to fn, fired after the path has been quiet for window.
+type debouncer struct {
+	mu     sync.Mutex
+	window time.Duration
+	timers map[string]*time.Timer
+	fn     func(path string)
+}
+
+func newDebouncer(window time.Duration, fn func(path string)) *debouncer {
+	return &debouncer{
+		window: window,
+		timers: make(map[string]*time.Timer),
+		fn:     fn,
+	}
+}
+
+func (d *debouncer) trigger(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if timer, ok := d.timers[path]; ok {
+		timer.Reset(d.window)
+		return
+	}
+
+	d.timers[path] = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		delete(d.timers, path)
+		d.mu.Unlock()
+		d.fn(path)
+	})
+}
+
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, timer := range d.timers {
+		timer.Stop()
 	}
 }
 
 func loadConfig(configPath, homeDir string) Config {
 	config := Config{
 		Options: Options{
-			DirectoriesToWalk:   []string{"~/Programs"},
-			ProbeInterval:       90,
-			IconDir:             filepath.Join(homeDir, ".local/share/icons"),
-			AppDir:              filepath.Join(homeDir, ".local/share/applications"),
-			ProbeExtensions:     []string{".AppBundle", ".blob"},
-			CorrectDesktopFiles: true,
+			DirectoriesToWalk:    []WatchDir{{Path: "~/Programs", Recursive: false}},
+			ProbeInterval:        90,
+			IconDir:              filepath.Join(homeDir, ".local/share/icons"),
+			AppDir:               filepath.Join(homeDir, ".local/share/applications"),
+			ProbeExtensions:      []string{".AppBundle", ".blob"},
+			CorrectDesktopFiles:  true,
+			FallbackProbeEnabled: false,
 		},
 		Tracker: make(map[string]*BundleEntry),
 	}
@@ -127,36 +698,48 @@ func processBundle(config Config, homeDir string) {
 	entries := config.Tracker
 	changed := false
 
-	for _, dir := range options.DirectoriesToWalk {
-		dir = strings.Replace(dir, "~", homeDir, 1)
-		log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Scanning directory: <green>%s</green>", dir))
-
+	for _, dir := range resolveWatchDirs(options.DirectoriesToWalk, homeDir) {
 		for _, ext := range options.ProbeExtensions {
-			bundles, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+			bundles, err := findBundles(dir.Path, ext, dir.Recursive)
 			if err != nil {
-				log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to scan directory <yellow>%s</yellow> for <yellow>%s</yellow> files: %v", dir, ext, err))
+				log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to scan directory <yellow>%s</yellow> for <yellow>%s</yellow> files: %v", dir.Path, ext, err))
 			}
 
 			for _, bundle := range bundles {
 				existing[bundle] = struct{}{}
 
-				sha := computeSHA(bundle)
-				if entry, checked := entries[bundle]; checked {
+				info, err := os.Stat(bundle)
+				if err != nil {
+					log.Println(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to stat file <yellow>%s</yellow>: <red>%v</red>", bundle, err))
+					continue
+				}
+
+				entry, checked := entries[bundle]
+				sha, quickHash := resolveSHA(bundle, info, entry)
+
+				if checked {
 					if entry == nil {
 						continue
 					}
 
 					if entry.SHA != sha {
 						if isExecutable(bundle) {
-							processBundles(bundle, sha, entries, options.IconDir, options.AppDir, config)
+							processBundles(bundle, sha, quickHash, info, entries, options.IconDir, options.AppDir, config)
 							changed = true
 						} else {
 							entries[bundle] = nil
 						}
+					} else if entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+						// Content is unchanged but the stat drifted (e.g. a bare `touch`); refresh
+						// the cached stat so the next probe can still skip the full re-hash.
+						entry.Size = info.Size()
+						entry.ModTime = info.ModTime()
+						entry.QuickHash = quickHash
+						changed = true
 					}
 				} else {
 					if isExecutable(bundle) {
-						processBundles(bundle, sha, entries, options.IconDir, options.AppDir, config)
+						processBundles(bundle, sha, quickHash, info, entries, options.IconDir, options.AppDir, config)
 						changed = true
 					} else {
 						entries[bundle] = nil
@@ -190,6 +773,31 @@ func isExecutable(path string) bool {
 	return mode&0111 != 0
 }
 
+// resolveSHA returns the SHA256 and quick hash for path. When the file's stat
+// matches the cached entry exactly, both cached hashes are reused without
+// reading the file. When only the mtime drifted (same size) and the quick hash
+// of the head/tail sample still matches, the change is taken to be a bare
+// `touch` and the cached SHA is reused without a full SHA256 read; a change
+// confined to the unsampled middle of a same-size file is not detected then.
+// Otherwise the full SHA256 is recomputed.
+func resolveSHA(path string, info os.FileInfo, entry *BundleEntry) (sha, quickHash string) {
+	if entry != nil && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		// Size and mtime both match the cached entry; nothing has changed, so
+		// skip the quick hash sample read entirely.
+		return entry.SHA, entry.QuickHash
+	}
+
+	quickHash = computeQuickHash(path, info.Size())
+
+	if entry != nil && entry.Size == info.Size() && entry.QuickHash == quickHash {
+		log.Println(tml.Sprintf("<blue><bold>INF:</bold></blue> Stat drifted for <yellow>%s</yellow> but the sampled content is unchanged; reusing cached SHA256", path))
+		return entry.SHA, quickHash
+	}
+
+	sha = computeSHA(path)
+	return sha, quickHash
+}
+
 func computeSHA(path string) string {
 	file, err := os.Open(path)
 	if err != nil {
@@ -199,7 +807,8 @@ func computeSHA(path string) string {
 	defer file.Close()
 
 	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
+	buf := make([]byte, shaBufferSize)
+	if _, err := io.CopyBuffer(hasher, file, buf); err != nil {
 		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to compute SHA256 for file <yellow>%s</yellow>: <red>%v</red>", path, err))
 		return ""
 	}
@@ -207,8 +816,46 @@ func computeSHA(path string) string {
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-func processBundles(path, sha string, entries map[string]*BundleEntry, iconPath, appPath string, cfg Config) {
-	entry := &BundleEntry{Path: path, SHA: sha}
+// computeQuickHash hashes only the first and last quickHashSampleSize bytes of
+// the file (the whole file if it's smaller than that), so a stat mismatch
+// caused by something like `touch` can be told apart from a real content
+// change without paying for a full SHA256 read.
+func computeQuickHash(path string, size int64) string {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to open file <yellow>%s</yellow>: <red>%v</red>", path, err))
+		return ""
+	}
+	defer file.Close()
+
+	hasher := crc64.New(crc64.MakeTable(crc64.ISO))
+
+	head := make([]byte, quickHashSampleSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to read file <yellow>%s</yellow>: <red>%v</red>", path, err))
+		return ""
+	}
+	hasher.Write(head[:n])
+
+	if size > quickHashSampleSize {
+		if _, err := file.Seek(-quickHashSampleSize, io.SeekEnd); err != nil {
+			log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to seek file <yellow>%s</yellow>: <red>%v</red>", path, err))
+			return ""
+		}
+		tail := make([]byte, quickHashSampleSize)
+		if _, err := io.ReadFull(file, tail); err != nil {
+			log.Fatalf(tml.Sprintf("<red><bold>ERR:</bold></red> Failed to read file <yellow>%s</yellow>: <red>%v</red>", path, err))
+			return ""
+		}
+		hasher.Write(tail)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func processBundles(path, sha, quickHash string, info os.FileInfo, entries map[string]*BundleEntry, iconPath, appPath string, cfg Config) {
+	entry := &BundleEntry{Path: path, SHA: sha, QuickHash: quickHash, Size: info.Size(), ModTime: info.ModTime()}
 	baseName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 
 	entry.Png = executeBundle(path, "--pbundle_pngIcon", filepath.Join(iconPath, baseName+".png"))